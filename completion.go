@@ -0,0 +1,145 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// completionEnvVar is the environment variable a generated completion script sets to switch Parse into completion mode.
+const completionEnvVar = "THORUH_COMPLETE"
+
+// completionFlagName is the hidden long option whose "="-attached value is the partial token being completed.
+const completionFlagName = "completion-bash-current"
+
+// UnsupportedShellError represents an error for when GenerateCompletion is asked to target a shell it doesn't know how to generate a script for.
+type UnsupportedShellError struct {
+	message string
+	name    string
+	Shell   string
+}
+
+func newUnsupportedShellError(shell string) UnsupportedShellError {
+	return UnsupportedShellError{
+		message: fmt.Sprintf("Shell \"%s\" isn't supported for completion generation.", shell),
+		name:    "UnsupportedShellError",
+		Shell:   shell,
+	}
+}
+
+func (error_ UnsupportedShellError) Error() string {
+	return error_.message
+}
+
+// GenerateCompletion writes a completion script for "bash", "zsh", or "fish" to w. The script re-invokes the program with THORUH_COMPLETE set so thoruh itself supplies the candidate list at completion time.
+func (options *Options) GenerateCompletion(shell string, w io.Writer) error {
+	programName := options.programName
+	if programName == "" {
+		programName = "program"
+	}
+	switch shell {
+	case "bash":
+		_, err := fmt.Fprintf(w, `_%[1]s_completions() {
+  local cur
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  COMPREPLY=( $(%[2]s=1 %[1]s --%[3]s="${cur}") )
+}
+complete -F _%[1]s_completions %[1]s
+`, programName, completionEnvVar, completionFlagName)
+		return err
+	case "zsh":
+		_, err := fmt.Fprintf(w, `#compdef %[1]s
+_%[1]s() {
+  local -a matches
+  matches=(${(f)"$(%[2]s=1 %[1]s --%[3]s="${words[CURRENT]}")"})
+  compadd -a matches
+}
+_%[1]s "$@"
+`, programName, completionEnvVar, completionFlagName)
+		return err
+	case "fish":
+		_, err := fmt.Fprintf(w, `function __%[1]s_complete
+  %[2]s=1 %[1]s --%[3]s=(commandline -ct)
+end
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`, programName, completionEnvVar, completionFlagName)
+		return err
+	default:
+		return newUnsupportedShellError(shell)
+	}
+}
+
+// detectCompletionRequest reports whether argv asks for completion candidates rather than a normal parse, returning the partial token to complete.
+func (options *Options) detectCompletionRequest() (string, bool) {
+	completionFlagPrefix := "--" + completionFlagName + "="
+	for _, argument := range options.argumentValues {
+		if strings.HasPrefix(argument, completionFlagPrefix) {
+			return argument[len(completionFlagPrefix):], true
+		}
+	}
+	if _, isSet := os.LookupEnv(completionEnvVar); isSet {
+		if len(options.argumentValues) == 0 {
+			return "", true
+		}
+		return options.argumentValues[len(options.argumentValues)-1], true
+	}
+	return "", false
+}
+
+// completionMatches filters registered long/short options and subcommand names down to those beginning with prefix. If prefix is itself a "--name=value" token, only that option's own CompleteFunc runs, against the "value" part alone.
+func (options *Options) completionMatches(prefix string) []string {
+	if strings.HasPrefix(prefix, "--") {
+		if equalSignIndex := strings.Index(prefix, "="); equalSignIndex != -1 {
+			name := prefix[2:equalSignIndex]
+			value := prefix[equalSignIndex+1:]
+			if descriptor, defined := options.longOptionDescriptors[name]; defined && descriptor.CompleteFunc != nil {
+				return descriptor.CompleteFunc(value)
+			}
+			return []string{}
+		}
+	}
+	matches := make([]string, 0)
+	for _, name := range sortedOptionNames(options.longOptionDescriptors) {
+		flag := "--" + name
+		if strings.HasPrefix(flag, prefix) {
+			matches = append(matches, flag)
+		}
+	}
+	for _, name := range sortedOptionNames(options.shortOptionDescriptors) {
+		flag := "-" + name
+		if strings.HasPrefix(flag, prefix) {
+			matches = append(matches, flag)
+		}
+	}
+	commandNames := make([]string, 0, len(options.commands))
+	for name := range options.commands {
+		commandNames = append(commandNames, name)
+	}
+	sort.Strings(commandNames)
+	for _, name := range commandNames {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
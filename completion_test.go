@@ -0,0 +1,54 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompletionMatches(t *testing.T) {
+	t.Run("A bare prefix matches flag names, not any CompleteFunc.", func(t *testing.T) {
+		t.Parallel()
+		options := NewOptions(nil)
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "repo", Type: OptionTypeLong, CompleteFunc: func(string) []string { return []string{"origin", "upstream"} }})
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "format", Type: OptionTypeLong, CompleteFunc: func(string) []string { return []string{"json", "yaml"} }})
+		matches := options.completionMatches("--re")
+		if !reflect.DeepEqual(matches, []string{"--repo"}) {
+			t.Fail()
+		}
+	})
+	t.Run("A \"--name=value\" token only dispatches to the matching option's CompleteFunc, with just the value part.", func(t *testing.T) {
+		t.Parallel()
+		var seenPrefix string
+		options := NewOptions(nil)
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "repo", Type: OptionTypeLong, CompleteFunc: func(string) []string { return []string{"origin", "upstream"} }})
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "format", Type: OptionTypeLong, CompleteFunc: func(prefix string) []string {
+			seenPrefix = prefix
+			return []string{"json", "yaml"}
+		}})
+		matches := options.completionMatches("--format=js")
+		if seenPrefix != "js" {
+			t.Fail()
+		}
+		if !reflect.DeepEqual(matches, []string{"json", "yaml"}) {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,176 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// UnknownConfigKeyError represents an error for when an INI config file references an option that isn't registered (optionally scoped to a subcommand section).
+type UnknownConfigKeyError struct {
+	message string
+	name    string
+	Key     string
+	Section string
+}
+
+func newUnknownConfigKeyError(section string, key string) UnknownConfigKeyError {
+	location := key
+	if section != "" {
+		location = fmt.Sprintf("%s.%s", section, key)
+	}
+	return UnknownConfigKeyError{
+		message: fmt.Sprintf("Config key \"%s\" doesn't match any registered option.", location),
+		name:    "UnknownConfigKeyError",
+		Key:     key,
+		Section: section,
+	}
+}
+
+func (error_ UnknownConfigKeyError) Error() string {
+	return error_.message
+}
+
+// LoadINI reads a "[section]"-scoped "key = value" INI config from r and records the values so Parse applies them with SourceConfig provenance — at lower precedence than the environment (Options.BindEnv) and the command line, but higher than a field's zero value. A "[section]" whose name matches a registered subcommand (see AddCommand) scopes its keys to that subcommand's own Options; keys outside any section are global. A key that matches neither a long nor a short option in scope returns an UnknownConfigKeyError.
+func (options *Options) LoadINI(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	section := ""
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		equalSignIndex := strings.Index(line, "=")
+		if equalSignIndex == -1 {
+			continue
+		}
+		key := strings.TrimSpace(line[:equalSignIndex])
+		value := strings.TrimSpace(line[(equalSignIndex + 1):])
+		target := options
+		if section != "" {
+			command, commandDefined := options.commands[section]
+			if !commandDefined {
+				return newUnknownConfigKeyError(section, key)
+			}
+			target = command.Options
+		}
+		if _, longDefined := target.longOptionDescriptors[key]; longDefined {
+			target.setConfigValue(OptionTypeLong, key, value)
+			continue
+		}
+		if _, shortDefined := target.shortOptionDescriptors[key]; shortDefined {
+			target.setConfigValue(OptionTypeShort, key, value)
+			continue
+		}
+		return newUnknownConfigKeyError(section, key)
+	}
+	return scanner.Err()
+}
+
+// BindEnv enables environment-variable fallback for every option registered on this Options instance: when an option isn't present on the command line, Parse looks up "<prefix><OPTION_NAME>" (the long option name upper-cased with "-" replaced by "_", falling back to the short name) and, if set, uses it with SourceEnv provenance.
+func (options *Options) BindEnv(prefix string) {
+	options.envPrefix = prefix
+}
+
+func (options *Options) setConfigValue(type_ optionType, name string, value string) {
+	if options.configValues == nil {
+		options.configValues = make(map[string]string)
+	}
+	options.configValues[configValueKey(type_, name)] = value
+}
+
+func configValueKey(type_ optionType, name string) string {
+	switch type_ {
+	case OptionTypeLong:
+		return "long:" + name
+	default:
+		return "short:" + name
+	}
+}
+
+func environmentVariableName(prefix string, name string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// mergeConfigAndEnvValues fills in, for every registered option with no value already parsed from the command line, a value sourced from the bound environment (highest remaining precedence) or else from a loaded config file, appending it to parsedArguments.Options. A still-unresolved Required option becomes a MissingRequiredOptionError entry in parsedArguments.Options.
+func (options *Options) mergeConfigAndEnvValues(parsedArguments *ParseResult) {
+	options.mergeDescriptorSourceValues(parsedArguments, options.longOptionDescriptors, OptionTypeLong)
+	options.mergeDescriptorSourceValues(parsedArguments, options.shortOptionDescriptors, OptionTypeShort)
+}
+
+func (options *Options) mergeDescriptorSourceValues(parsedArguments *ParseResult, descriptors map[string]Option, type_ optionType) {
+	for name, descriptor := range descriptors {
+		if hasParsedOptionValue(parsedArguments, type_, name) {
+			continue
+		}
+		if options.envPrefix != "" {
+			if envValue, isSet := os.LookupEnv(environmentVariableName(options.envPrefix, name)); isSet {
+				parsedArguments.Options = append(parsedArguments.Options, ParsedOption{
+					Value: ParsedOptionValue{
+						Argument:     envValue,
+						ArgumentType: descriptor.ArgumentType,
+						Name:         name,
+						Source:       SourceEnv,
+						Type:         type_,
+					},
+				})
+				continue
+			}
+		}
+		if configValue, isConfigured := options.configValues[configValueKey(type_, name)]; isConfigured {
+			parsedArguments.Options = append(parsedArguments.Options, ParsedOption{
+				Value: ParsedOptionValue{
+					Argument:     configValue,
+					ArgumentType: descriptor.ArgumentType,
+					Name:         name,
+					Source:       SourceConfig,
+					Type:         type_,
+				},
+			})
+			continue
+		}
+		if descriptor.Required {
+			parsedArguments.Options = append(parsedArguments.Options, ParsedOption{
+				Error: true,
+				Value: newMissingRequiredOptionError(name),
+			})
+		}
+	}
+}
+
+func hasParsedOptionValue(parsedArguments *ParseResult, type_ optionType, name string) bool {
+	for _, parsedOption := range parsedArguments.Options {
+		if parsedOption.Error {
+			continue
+		}
+		parsedValue := parsedOption.Value.(ParsedOptionValue)
+		if parsedValue.Type == type_ && parsedValue.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadINIAndBindEnv(t *testing.T) {
+	t.Run("A config value fills in a missing option with SourceConfig provenance.", func(t *testing.T) {
+		t.Parallel()
+		options := NewOptions(nil)
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "repo", Type: OptionTypeLong})
+		if err := options.LoadINI(strings.NewReader("repo = origin\n")); err != nil {
+			t.Fatal(err)
+		}
+		optionsResult := options.Parse()
+		if len(optionsResult.Options) != 1 {
+			t.Fail()
+		}
+		parsedValue := optionsResult.Options[0].Value.(ParsedOptionValue)
+		if parsedValue.Argument != "origin" || parsedValue.Source != SourceConfig {
+			t.Fail()
+		}
+	})
+	t.Run("An unrecognized config key reports UnknownConfigKeyError.", func(t *testing.T) {
+		t.Parallel()
+		options := NewOptions(nil)
+		if err := options.LoadINI(strings.NewReader("bogus = value\n")); err == nil {
+			t.Fail()
+		} else if _, ok := err.(UnknownConfigKeyError); !ok {
+			t.Fail()
+		}
+	})
+	t.Run("A bound environment variable outranks a config value.", func(t *testing.T) {
+		t.Setenv("THORUH_TEST_REPO", "upstream")
+		options := NewOptions(nil)
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "repo", Type: OptionTypeLong})
+		options.BindEnv("THORUH_TEST_")
+		if err := options.LoadINI(strings.NewReader("repo = origin\n")); err != nil {
+			t.Fatal(err)
+		}
+		optionsResult := options.Parse()
+		parsedValue := optionsResult.Options[0].Value.(ParsedOptionValue)
+		if parsedValue.Argument != "upstream" || parsedValue.Source != SourceEnv {
+			t.Fail()
+		}
+	})
+	t.Run("Neither BindEnv nor LoadINI were used: an unset Required option is left for the caller, not turned into a phantom error.", func(t *testing.T) {
+		t.Parallel()
+		options := NewOptions(nil)
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "repo", Required: true, Type: OptionTypeLong})
+		optionsResult := options.Parse()
+		if len(optionsResult.Options) != 0 {
+			t.Fail()
+		}
+	})
+}
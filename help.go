@@ -0,0 +1,211 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// helpColumnWidth is the assumed terminal width used to wrap help descriptions.
+const helpColumnWidth = 80
+
+// SetProgramName sets the program name used when synthesizing the usage line in FormatHelp.
+func (options *Options) SetProgramName(programName string) {
+	options.programName = programName
+}
+
+// SetDescription sets the free-form description printed below the usage line in FormatHelp.
+func (options *Options) SetDescription(description string) {
+	options.description = description
+}
+
+// EnableHelp registers the built-in "-h"/"--help" flags. Once enabled, if either is parsed, Parse stops consuming further argument values and the returned ParseResult has HelpRequested set to true.
+func (options *Options) EnableHelp() {
+	options.AddOption(Option{
+		ArgumentType:    OptionArgumentTypeNone,
+		HelpDescription: "Show this help message and exit.",
+		Name:            "help",
+		Type:            OptionTypeLong,
+	})
+	options.AddOption(Option{
+		ArgumentType:    OptionArgumentTypeNone,
+		HelpDescription: "Show this help message and exit.",
+		Name:            "h",
+		Type:            OptionTypeShort,
+	})
+	options.helpEnabled = true
+}
+
+// FormatHelp writes a two-column help screen to w: a synthesized usage line, the registered description (if any), one row per registered option with its flag(s) on the left and its HelpDescription, word-wrapped, on the right, and, if any subcommands are registered (see AddCommand), a "Commands:" section walking the full command tree.
+func (options *Options) FormatHelp(w io.Writer) {
+	fmt.Fprintln(w, options.formatUsageLine())
+	if options.description != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, options.description)
+	}
+	rows := options.helpRows()
+	if len(rows) != 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Options:")
+		flagColumnWidth := 0
+		for _, row := range rows {
+			if len(row.flag) > flagColumnWidth {
+				flagColumnWidth = len(row.flag)
+			}
+		}
+		descriptionColumnWidth := helpColumnWidth - flagColumnWidth - 4
+		if descriptionColumnWidth < 20 {
+			descriptionColumnWidth = 20
+		}
+		for _, row := range rows {
+			descriptionLines := wrapHelpText(row.description, descriptionColumnWidth)
+			if len(descriptionLines) == 0 {
+				descriptionLines = []string{""}
+			}
+			fmt.Fprintf(w, "  %-*s  %s\n", flagColumnWidth, row.flag, descriptionLines[0])
+			for _, line := range descriptionLines[1:] {
+				fmt.Fprintf(w, "  %s  %s\n", strings.Repeat(" ", flagColumnWidth), line)
+			}
+		}
+	}
+	if len(options.commands) != 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Commands:")
+		options.formatCommandRows(w, 0)
+	}
+}
+
+// formatCommandRows writes one indented row per subcommand registered on options, recursing into each subcommand's own commands so the whole tree is walked.
+func (options *Options) formatCommandRows(w io.Writer, depth int) {
+	names := sortedCommandNames(options.commands)
+	flagColumnWidth := 0
+	for _, name := range names {
+		if len(name) > flagColumnWidth {
+			flagColumnWidth = len(name)
+		}
+	}
+	indent := strings.Repeat("  ", depth+1)
+	for _, name := range names {
+		command := options.commands[name]
+		fmt.Fprintf(w, "%s%-*s  %s\n", indent, flagColumnWidth, name, command.Options.description)
+		if len(command.Options.commands) != 0 {
+			command.Options.formatCommandRows(w, depth+1)
+		}
+	}
+}
+
+type helpRow struct {
+	description string
+	flag        string
+}
+
+func (options *Options) helpRows() []helpRow {
+	rows := make([]helpRow, 0, len(options.longOptionDescriptors)+len(options.shortOptionDescriptors))
+	for _, name := range sortedOptionNames(options.longOptionDescriptors) {
+		descriptor := options.longOptionDescriptors[name]
+		rows = append(rows, helpRow{description: descriptor.HelpDescription, flag: formatHelpFlag(descriptor, "--"+name)})
+	}
+	for _, name := range sortedOptionNames(options.shortOptionDescriptors) {
+		descriptor := options.shortOptionDescriptors[name]
+		rows = append(rows, helpRow{description: descriptor.HelpDescription, flag: formatHelpFlag(descriptor, "-"+name)})
+	}
+	return rows
+}
+
+func (options *Options) formatUsageLine() string {
+	programName := options.programName
+	if programName == "" {
+		programName = "program"
+	}
+	parts := []string{fmt.Sprintf("Usage: %s", programName)}
+	for _, name := range sortedOptionNames(options.longOptionDescriptors) {
+		parts = append(parts, formatUsageToken(options.longOptionDescriptors[name], "--"+name))
+	}
+	for _, name := range sortedOptionNames(options.shortOptionDescriptors) {
+		parts = append(parts, formatUsageToken(options.shortOptionDescriptors[name], "-"+name))
+	}
+	if len(options.commands) != 0 {
+		parts = append(parts, fmt.Sprintf("<%s>", strings.Join(sortedCommandNames(options.commands), "|")), "[<args>...]")
+	}
+	return strings.Join(parts, " ")
+}
+
+func sortedCommandNames(commands map[string]*Command) []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func formatUsageToken(descriptor Option, flag string) string {
+	token := flag
+	if descriptor.ArgumentType != OptionArgumentTypeNone {
+		token = fmt.Sprintf("%s=%s", flag, strings.ToUpper(descriptor.Name))
+	}
+	if descriptor.Required {
+		return token
+	}
+	return fmt.Sprintf("[%s]", token)
+}
+
+func formatHelpFlag(descriptor Option, flag string) string {
+	if descriptor.ArgumentType == OptionArgumentTypeNone {
+		return flag
+	}
+	return fmt.Sprintf("%s=%s", flag, strings.ToUpper(descriptor.Name))
+}
+
+func sortedOptionNames(descriptors map[string]Option) []string {
+	names := make([]string, 0, len(descriptors))
+	for name := range descriptors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func wrapHelpText(text string, width int) []string {
+	if text == "" {
+		return nil
+	}
+	words := strings.Fields(text)
+	lines := make([]string, 0)
+	currentLine := ""
+	for _, word := range words {
+		if currentLine == "" {
+			currentLine = word
+			continue
+		}
+		if len(currentLine)+1+len(word) > width {
+			lines = append(lines, currentLine)
+			currentLine = word
+			continue
+		}
+		currentLine = currentLine + " " + word
+	}
+	if currentLine != "" {
+		lines = append(lines, currentLine)
+	}
+	return lines
+}
@@ -0,0 +1,75 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFormatHelp(t *testing.T) {
+	t.Run("Usage line and Options: section cover registered flags, without a hardcoded positional placeholder.", func(t *testing.T) {
+		t.Parallel()
+		options := NewOptions(nil)
+		options.SetProgramName("tool")
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, HelpDescription: "Repository to use.", Name: "repo", Required: true, Type: OptionTypeLong})
+		var buffer bytes.Buffer
+		options.FormatHelp(&buffer)
+		output := buffer.String()
+		if !strings.Contains(output, "Usage: tool --repo=REPO") {
+			t.Fail()
+		}
+		if strings.Contains(output, "FILES") {
+			t.Fail()
+		}
+		if !strings.Contains(output, "Options:") || !strings.Contains(output, "Repository to use.") {
+			t.Fail()
+		}
+		if strings.Contains(output, "Commands:") {
+			t.Fail()
+		}
+	})
+	t.Run("Registered subcommands appear in the usage line and a recursive Commands: section.", func(t *testing.T) {
+		t.Parallel()
+		options := NewOptions(nil)
+		options.SetProgramName("tool")
+		backup := NewOptions(nil)
+		backup.SetDescription("Back up a repository.")
+		snapshot := NewOptions(nil)
+		snapshot.SetDescription("Take a snapshot.")
+		backup.AddCommand("snapshot", snapshot)
+		options.AddCommand("backup", backup)
+		var buffer bytes.Buffer
+		options.FormatHelp(&buffer)
+		output := buffer.String()
+		if !strings.Contains(output, "Usage: tool <backup>") {
+			t.Fail()
+		}
+		if !strings.Contains(output, "Commands:") {
+			t.Fail()
+		}
+		if !strings.Contains(output, "backup") || !strings.Contains(output, "Back up a repository.") {
+			t.Fail()
+		}
+		if !strings.Contains(output, "snapshot") || !strings.Contains(output, "Take a snapshot.") {
+			t.Fail()
+		}
+	})
+}
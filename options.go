@@ -20,6 +20,7 @@ package thoruh
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
 	"unicode/utf8"
@@ -32,6 +33,8 @@ const (
 	OptionArgumentTypeNone optionArgumentType = iota
 	// OptionArgumentTypeRequired represents the argument type for an option for which an argument is required.
 	OptionArgumentTypeRequired
+	// OptionArgumentTypeOptional represents the argument type for an option whose argument is optional: for a long option, only an "="-attached value counts as the argument; for a short option, only the remainder of the current cluster counts. Neither form ever consumes the next argument value.
+	OptionArgumentTypeOptional
 )
 
 type optionType uint
@@ -43,11 +46,27 @@ const (
 	OptionTypeShort
 )
 
+type optionValueSource uint
+
+const (
+	// SourceDefault represents a value that wasn't provided anywhere and was left at its zero/default value.
+	SourceDefault optionValueSource = iota
+	// SourceConfig represents a value loaded from a config file via Options.LoadINI.
+	SourceConfig
+	// SourceEnv represents a value read from an environment variable via Options.BindEnv.
+	SourceEnv
+	// SourceCLI represents a value provided on the command line.
+	SourceCLI
+)
+
 // Option represents a command-line option.
 type Option struct {
-	ArgumentType optionArgumentType
-	Name         string
-	Type         optionType
+	ArgumentType    optionArgumentType
+	CompleteFunc    func(prefix string) []string
+	HelpDescription string
+	Name            string
+	Required        bool
+	Type            optionType
 }
 
 // ExtraneousOptionArgumentParseError represents an error for when an argument is provided to an option which doesn't accept an argument.
@@ -80,6 +99,7 @@ type ParsedOptionValue struct {
 	Argument     string
 	ArgumentType optionArgumentType
 	Name         string
+	Source       optionValueSource
 	Type         optionType
 }
 
@@ -92,22 +112,40 @@ type ParsedOption struct {
 // ParseResult is the structure returned by the parser, containing the results.
 // ParseResult.Options is a slice of "parsed options".
 // ParseResult.RemainingArgumentValues is a slice containing the remaining argument values when parsing stopped.
+// ParseResult.Command is the subcommand which matched the first remaining argument value, if any were registered via Options.AddCommand.
+// ParseResult.CommandPath is the full chain of matched subcommand names, from outermost to innermost.
 type ParseResult struct {
+	Command                 *Command
+	CommandPath             []string
+	CompletionHandled       bool
+	HelpRequested           bool
 	Options                 []ParsedOption
 	RemainingArgumentValues []string
 }
 
+// Command represents a registered subcommand and the nested Options parser which handles its own flags and remaining argument values.
+type Command struct {
+	Name    string
+	Options *Options
+}
+
 // Options represents an options parser.
 type Options struct {
-	argumentValues                    [][]rune
+	argumentValues                    []string
+	commands                          map[string]*Command
+	configValues                      map[string]string
+	description                       string
+	envPrefix                         string
+	helpEnabled                       bool
 	longOptionDescriptors             map[string]Option
 	nextArgumentValueIndex            uint
 	Parsed                            *ParseResult
+	programName                       string
 	shortOptionDescriptors            map[string]Option
 	skipArgumentsOnNextParseIteration uint
 }
 
-func newExtraneousOptionArgumentParseError(options *Options, optionType optionType, optionName []rune, optionArgument []rune) ExtraneousOptionArgumentParseError {
+func newExtraneousOptionArgumentParseError(options *Options, optionType optionType, optionName string, optionArgument string) ExtraneousOptionArgumentParseError {
 	var optionPrefix string
 	switch optionType {
 	case OptionTypeLong:
@@ -115,12 +153,12 @@ func newExtraneousOptionArgumentParseError(options *Options, optionType optionTy
 	case OptionTypeShort:
 		optionPrefix = "-"
 	}
-	message := fmt.Sprintf("Extraneous argument \"%s\" passed to option \"%s%s\".", string(optionArgument), optionPrefix, string(optionName))
+	message := fmt.Sprintf("Extraneous argument \"%s\" passed to option \"%s%s\".", optionArgument, optionPrefix, optionName)
 	return ExtraneousOptionArgumentParseError{
 		message:        message,
 		name:           "ExtraneousOptionArgumentParseError",
-		OptionArgument: string(optionArgument),
-		OptionName:     string(optionName),
+		OptionArgument: optionArgument,
+		OptionName:     optionName,
 		OptionType:     optionType,
 	}
 }
@@ -129,7 +167,7 @@ func (error_ ExtraneousOptionArgumentParseError) Error() string {
 	return error_.message
 }
 
-func newMissingOptionArgumentParseError(options *Options, optionType optionType, optionName []rune) MissingOptionArgumentParseError {
+func newMissingOptionArgumentParseError(options *Options, optionType optionType, optionName string) MissingOptionArgumentParseError {
 	var optionPrefix string
 	switch optionType {
 	case OptionTypeLong:
@@ -137,11 +175,11 @@ func newMissingOptionArgumentParseError(options *Options, optionType optionType,
 	case OptionTypeShort:
 		optionPrefix = "-"
 	}
-	message := fmt.Sprintf("Option \"%s%s\" expects an argument.", optionPrefix, string(optionName))
+	message := fmt.Sprintf("Option \"%s%s\" expects an argument.", optionPrefix, optionName)
 	return MissingOptionArgumentParseError{
 		message:    message,
 		name:       "MissingOptionArgumentParseError",
-		OptionName: string(optionName),
+		OptionName: optionName,
 		OptionType: optionType,
 	}
 }
@@ -150,7 +188,7 @@ func (error_ MissingOptionArgumentParseError) Error() string {
 	return error_.message
 }
 
-func newUnknownOptionParseError(options *Options, optionType optionType, optionName []rune) UnknownOptionParseError {
+func newUnknownOptionParseError(options *Options, optionType optionType, optionName string) UnknownOptionParseError {
 	var optionPrefix string
 	switch optionType {
 	case OptionTypeLong:
@@ -158,11 +196,11 @@ func newUnknownOptionParseError(options *Options, optionType optionType, optionN
 	case OptionTypeShort:
 		optionPrefix = "-"
 	}
-	message := fmt.Sprintf("Option \"%s%s\" is unknown.", optionPrefix, string(optionName))
+	message := fmt.Sprintf("Option \"%s%s\" is unknown.", optionPrefix, optionName)
 	return UnknownOptionParseError{
 		message:    message,
 		name:       "UnknownOptionParseError",
-		OptionName: string(optionName),
+		OptionName: optionName,
 		OptionType: optionType,
 	}
 }
@@ -173,10 +211,8 @@ func (error_ UnknownOptionParseError) Error() string {
 
 // NewOptions creates a new instance of Options and returns the pointer to it.
 func NewOptions(argumentValues []string) *Options {
-	_argumentValues := make([][]rune, len(argumentValues))
-	for argumentIndex, argumentValue := range argumentValues {
-		_argumentValues[argumentIndex] = []rune(argumentValue)
-	}
+	_argumentValues := make([]string, len(argumentValues))
+	copy(_argumentValues, argumentValues)
 	options := Options{
 		argumentValues:                    _argumentValues,
 		longOptionDescriptors:             make(map[string]Option),
@@ -205,20 +241,59 @@ func (options *Options) AddOptions(descriptors []Option) {
 	}
 }
 
+// AddCommand registers a subcommand under the given name. Once Parse has consumed the receiver's own flags, if the first remaining argument value matches name, sub takes over parsing the rest of the argument values and its result is attached to the parent's ParseResult.
+func (options *Options) AddCommand(name string, sub *Options) *Command {
+	if options.commands == nil {
+		options.commands = make(map[string]*Command)
+	}
+	command := &Command{
+		Name:    name,
+		Options: sub,
+	}
+	options.commands[name] = command
+	return command
+}
+
 func (options *Options) incrementNextArgumentValueIndex() {
 	options.nextArgumentValueIndex++
 }
 
+// resultsRequestHelp reports whether any of the given parsed options is the built-in "-h"/"--help" flag.
+func (options *Options) resultsRequestHelp(parsedOptions []ParsedOption) bool {
+	if !options.helpEnabled {
+		return false
+	}
+	for _, parsedOption := range parsedOptions {
+		if parsedOption.Error {
+			continue
+		}
+		parsedValue := parsedOption.Value.(ParsedOptionValue)
+		if (parsedValue.Type == OptionTypeLong && parsedValue.Name == "help") ||
+			(parsedValue.Type == OptionTypeShort && parsedValue.Name == "h") {
+			return true
+		}
+	}
+	return false
+}
+
 // Parse runs the options parser.
 func (options *Options) Parse() *ParseResult {
 	if options.Parsed != nil {
 		return options.Parsed
 	}
+	if completionPrefix, completionRequested := options.detectCompletionRequest(); completionRequested {
+		for _, match := range options.completionMatches(completionPrefix) {
+			fmt.Fprintln(os.Stdout, match)
+		}
+		options.Parsed = &ParseResult{CompletionHandled: true}
+		return options.Parsed
+	}
 	results := make([]ParsedOption, 0)
+	helpRequested := false
 	for _, argumentValue := range options.argumentValues {
-		argumentValue = []rune(strings.TrimSpace(string(argumentValue)))
+		argumentValue = strings.TrimSpace(argumentValue)
 		// NOTE(@jonathanmarvens): This check is likely unnecessary, but I'm leaving it just in case.
-		if string(argumentValue) == "" {
+		if argumentValue == "" {
 			options.incrementNextArgumentValueIndex()
 			continue
 		}
@@ -229,84 +304,100 @@ func (options *Options) Parse() *ParseResult {
 		}
 		if runtime.GOOS == "windows" &&
 			argumentValue[0] == '/' {
-			if string(argumentValue) == "/" {
+			if argumentValue == "/" {
 				break
 			}
 			optionName := argumentValue[1:]
-			if utf8.RuneCountInString(string(optionName)) == 1 ||
-				(utf8.RuneCountInString(string(optionName)) >= 2 &&
+			if utf8.RuneCountInString(optionName) == 1 ||
+				(len(optionName) >= 2 &&
 					optionName[1] == ':') ||
-				!strings.ContainsRune(string(optionName), ':') {
+				!strings.ContainsRune(optionName, ':') {
 				shortOptionResults := options.parseShortOptions(optionName, true)
 				results = append(results, shortOptionResults...)
+				helpRequested = helpRequested || options.resultsRequestHelp(shortOptionResults)
 			} else {
 				longOptionResult := options.parseLongOption(optionName, true)
 				results = append(results, longOptionResult)
+				helpRequested = helpRequested || options.resultsRequestHelp([]ParsedOption{longOptionResult})
+			}
+			if helpRequested {
+				break
 			}
 		} else if argumentValue[0] == '-' {
-			if string(argumentValue) == "-" {
+			if argumentValue == "-" {
 				break
 			}
-			if utf8.RuneCountInString(string(argumentValue)) >= 2 &&
+			if len(argumentValue) >= 2 &&
 				argumentValue[1] == '-' {
-				if string(argumentValue) == "--" {
+				if argumentValue == "--" {
 					options.incrementNextArgumentValueIndex()
 					break
 				}
 				optionName := argumentValue[2:]
 				longOptionResult := options.parseLongOption(optionName, false)
 				results = append(results, longOptionResult)
+				helpRequested = helpRequested || options.resultsRequestHelp([]ParsedOption{longOptionResult})
 			} else {
 				optionName := argumentValue[1:]
 				shortOptionResults := options.parseShortOptions(optionName, false)
 				results = append(results, shortOptionResults...)
+				helpRequested = helpRequested || options.resultsRequestHelp(shortOptionResults)
+			}
+			if helpRequested {
+				break
 			}
+		} else {
+			break
 		}
 	}
 	parsedArguments := ParseResult{
+		HelpRequested:           helpRequested,
 		Options:                 make([]ParsedOption, len(results)),
 		RemainingArgumentValues: make([]string, 0),
 	}
 	copy(parsedArguments.Options, results)
 	remainingArgumentValues := options.argumentValues[options.nextArgumentValueIndex:]
-	for _, argumentValue := range remainingArgumentValues {
-		parsedArguments.RemainingArgumentValues = append(parsedArguments.RemainingArgumentValues, string(argumentValue))
+	parsedArguments.RemainingArgumentValues = append(parsedArguments.RemainingArgumentValues, remainingArgumentValues...)
+	if !helpRequested && (options.envPrefix != "" || options.configValues != nil) {
+		options.mergeConfigAndEnvValues(&parsedArguments)
+	}
+	if !helpRequested && len(options.commands) != 0 && len(parsedArguments.RemainingArgumentValues) != 0 {
+		commandName := parsedArguments.RemainingArgumentValues[0]
+		if command, commandDefined := options.commands[commandName]; commandDefined {
+			subArgumentValues := make([]string, len(parsedArguments.RemainingArgumentValues)-1)
+			copy(subArgumentValues, parsedArguments.RemainingArgumentValues[1:])
+			command.Options.argumentValues = subArgumentValues
+			command.Options.nextArgumentValueIndex = 0
+			command.Options.Parsed = nil
+			subResult := command.Options.Parse()
+			parsedArguments.Command = command
+			parsedArguments.CommandPath = append([]string{commandName}, subResult.CommandPath...)
+			parsedArguments.RemainingArgumentValues = subResult.RemainingArgumentValues
+		}
 	}
 	options.Parsed = &parsedArguments
 	return options.Parsed
 }
 
-func (options *Options) parseLongOption(optionName []rune, dosPrefix bool) ParsedOption {
+// parseLongOption parses a single "--"-prefixed (or, on Windows, "/"-prefixed) option out of optionName, which is the argument value with its prefix already stripped. It operates directly on the original string with byte indexes rather than round-tripping through []rune.
+func (options *Options) parseLongOption(optionName string, dosPrefix bool) ParsedOption {
 	var result ParsedOption
-	var optionArgument []rune
+	var optionArgument string
+	hasArgument := false
 	if dosPrefix {
-		if optionColonSignIndex := strings.IndexRune(string(optionName), ':'); optionColonSignIndex != -1 {
-			if len(string(optionName)) >= (optionColonSignIndex + 1) {
-				optionArgumentString := string(optionName)[(optionColonSignIndex + 1):]
-				optionArgument = make([]rune, 0)
-				for i := uint(0); i < uint(len(optionArgumentString)); {
-					optionArgumentRune, optionArgumentRuneWidth := utf8.DecodeRuneInString(optionArgumentString[i:])
-					optionArgument = append(optionArgument, optionArgumentRune)
-					i += uint(optionArgumentRuneWidth)
-				}
-			}
-			optionName = []rune(string(optionName)[:optionColonSignIndex])
+		if optionColonSignIndex := strings.IndexByte(optionName, ':'); optionColonSignIndex != -1 {
+			optionArgument = optionName[optionColonSignIndex+1:]
+			hasArgument = true
+			optionName = optionName[:optionColonSignIndex]
 		}
 	} else {
-		if optionEqualSignIndex := strings.IndexRune(string(optionName), '='); optionEqualSignIndex != -1 {
-			if len(string(optionName)) >= (optionEqualSignIndex + 1) {
-				optionArgumentString := string(optionName)[(optionEqualSignIndex + 1):]
-				optionArgument = make([]rune, 0)
-				for i := uint(0); i < uint(len(optionArgumentString)); {
-					optionArgumentRune, optionArgumentRuneWidth := utf8.DecodeRuneInString(optionArgumentString[i:])
-					optionArgument = append(optionArgument, optionArgumentRune)
-					i += uint(optionArgumentRuneWidth)
-				}
-			}
-			optionName = []rune(string(optionName)[:optionEqualSignIndex])
+		if optionEqualSignIndex := strings.IndexByte(optionName, '='); optionEqualSignIndex != -1 {
+			optionArgument = optionName[optionEqualSignIndex+1:]
+			hasArgument = true
+			optionName = optionName[:optionEqualSignIndex]
 		}
 	}
-	if _, optionDefined := options.longOptionDescriptors[string(optionName)]; !optionDefined {
+	if _, optionDefined := options.longOptionDescriptors[optionName]; !optionDefined {
 		result = ParsedOption{
 			Error: true,
 			Value: newUnknownOptionParseError(options, OptionTypeLong, optionName),
@@ -314,10 +405,10 @@ func (options *Options) parseLongOption(optionName []rune, dosPrefix bool) Parse
 		options.incrementNextArgumentValueIndex()
 		return result
 	}
-	optionDescriptor := options.longOptionDescriptors[string(optionName)]
+	optionDescriptor := options.longOptionDescriptors[optionName]
 	switch optionDescriptor.ArgumentType {
 	case OptionArgumentTypeNone:
-		if optionArgument != nil {
+		if hasArgument {
 			result = ParsedOption{
 				Error: true,
 				Value: newExtraneousOptionArgumentParseError(options, OptionTypeLong, optionName, optionArgument),
@@ -326,7 +417,7 @@ func (options *Options) parseLongOption(optionName []rune, dosPrefix bool) Parse
 			return result
 		}
 	case OptionArgumentTypeRequired:
-		if optionArgument == nil {
+		if !hasArgument {
 			remainingArgumentValues := options.argumentValues[(options.nextArgumentValueIndex + uint(1)):]
 			if len(remainingArgumentValues) == 0 {
 				result = ParsedOption{
@@ -336,17 +427,19 @@ func (options *Options) parseLongOption(optionName []rune, dosPrefix bool) Parse
 				options.incrementNextArgumentValueIndex()
 				return result
 			}
-			optionArgument = make([]rune, len(remainingArgumentValues[0]))
-			copy(optionArgument, remainingArgumentValues[0])
+			optionArgument = remainingArgumentValues[0]
 			options.skipArgumentsOnNextParseIteration++
 		}
+	case OptionArgumentTypeOptional:
+		// NOTE: an optional argument is only ever taken from an "="-attached value; the next argument value is never consumed.
 	}
 	result = ParsedOption{
 		Error: false,
 		Value: ParsedOptionValue{
-			Argument:     string(optionArgument),
+			Argument:     optionArgument,
 			ArgumentType: optionDescriptor.ArgumentType,
-			Name:         string(optionName),
+			Name:         optionName,
+			Source:       SourceCLI,
 			Type:         optionDescriptor.Type,
 		},
 	}
@@ -354,79 +447,61 @@ func (options *Options) parseLongOption(optionName []rune, dosPrefix bool) Parse
 	return result
 }
 
-func (options *Options) parseShortOptions(optionNameRunes []rune, dosPrefix bool) []ParsedOption {
+// parseShortOptions parses a "-"-prefixed (or, on Windows, "/"-prefixed) cluster of bundled short options out of optionName, which is the argument value with its prefix already stripped. It walks optionName forward one rune at a time via utf8.DecodeRuneInString instead of converting to and from []rune. A None-type option only ever consumes its own character, leaving the rest of the cluster for the next iteration; only a Required/Optional option claims the cluster remainder, and only as the last option considered. The whole cluster is a single argument value, so options.nextArgumentValueIndex is advanced exactly once, after the cluster has been fully scanned.
+func (options *Options) parseShortOptions(optionName string, dosPrefix bool) []ParsedOption {
 	results := make([]ParsedOption, 0)
-	skipArgumentsOnNextLocalParseIteration := uint(0)
-	for i := uint(0); i < uint(len(string(optionNameRunes))); {
-		optionNameString := string(optionNameRunes)[i:]
-		_, optionNameRuneWidth := utf8.DecodeRuneInString(optionNameString)
-		i += uint(optionNameRuneWidth)
-		if skipArgumentsOnNextLocalParseIteration != uint(0) {
-			skipArgumentsOnNextLocalParseIteration--
-			continue
-		}
-		optionName := []rune(optionNameString)[0]
-		optionArgument := []rune(optionNameString[1:])
-		if dosPrefix {
-			if strings.IndexRune(string(optionArgument), ':') == 0 {
-				optionArgument = []rune(string(optionArgument)[1:])
-				skipArgumentsOnNextLocalParseIteration++
-			}
-		}
-		if string(optionArgument) != "" {
-			for range optionArgument {
-				skipArgumentsOnNextLocalParseIteration++
-			}
-		}
-		if _, optionDefined := options.shortOptionDescriptors[string([]rune{optionName})]; !optionDefined {
+	for i := 0; i < len(optionName); {
+		_, currentOptionNameWidth := utf8.DecodeRuneInString(optionName[i:])
+		currentOptionName := optionName[i : i+currentOptionNameWidth]
+		i += currentOptionNameWidth
+		if _, optionDefined := options.shortOptionDescriptors[currentOptionName]; !optionDefined {
 			result := ParsedOption{
 				Error: true,
-				Value: newUnknownOptionParseError(options, OptionTypeShort, []rune{optionName}),
+				Value: newUnknownOptionParseError(options, OptionTypeShort, currentOptionName),
 			}
-			options.incrementNextArgumentValueIndex()
 			results = append(results, result)
 			continue
 		}
-		optionDescriptor := options.shortOptionDescriptors[string([]rune{optionName})]
+		optionDescriptor := options.shortOptionDescriptors[currentOptionName]
+		var optionArgument string
 		switch optionDescriptor.ArgumentType {
 		case OptionArgumentTypeNone:
-			if string(optionArgument) != "" {
-				result := ParsedOption{
-					Error: true,
-					Value: newExtraneousOptionArgumentParseError(options, OptionTypeShort, []rune{optionName}, optionArgument),
-				}
-				options.incrementNextArgumentValueIndex()
-				results = append(results, result)
-				continue
+			// A None-type option never claims the cluster remainder; it consumes only its own character here.
+		case OptionArgumentTypeRequired, OptionArgumentTypeOptional:
+			remainder := optionName[i:]
+			if dosPrefix && strings.HasPrefix(remainder, ":") {
+				remainder = remainder[1:]
 			}
-		case OptionArgumentTypeRequired:
-			if string(optionArgument) == "" {
+			if remainder != "" {
+				optionArgument = remainder
+				i = len(optionName)
+			} else if optionDescriptor.ArgumentType == OptionArgumentTypeRequired {
 				remainingArgumentValues := options.argumentValues[(options.nextArgumentValueIndex + uint(1)):]
 				if len(remainingArgumentValues) == 0 {
 					result := ParsedOption{
 						Error: true,
-						Value: newMissingOptionArgumentParseError(options, OptionTypeShort, []rune{optionName}),
+						Value: newMissingOptionArgumentParseError(options, OptionTypeShort, currentOptionName),
 					}
-					options.incrementNextArgumentValueIndex()
 					results = append(results, result)
 					continue
 				}
-				optionArgument = make([]rune, len(remainingArgumentValues[0]))
-				copy(optionArgument, remainingArgumentValues[0])
+				optionArgument = remainingArgumentValues[0]
 				options.skipArgumentsOnNextParseIteration++
 			}
+			// NOTE: an optional argument with no cluster remainder is left empty; the next argument value is never consumed for it.
 		}
 		result := ParsedOption{
 			Error: false,
 			Value: ParsedOptionValue{
-				Argument:     string(optionArgument),
+				Argument:     optionArgument,
 				ArgumentType: optionDescriptor.ArgumentType,
-				Name:         string([]rune{optionName}),
+				Name:         currentOptionName,
+				Source:       SourceCLI,
 				Type:         optionDescriptor.Type,
 			},
 		}
-		options.incrementNextArgumentValueIndex()
 		results = append(results, result)
 	}
+	options.incrementNextArgumentValueIndex()
 	return results
 }
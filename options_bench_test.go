@@ -0,0 +1,77 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"testing"
+)
+
+func newBenchmarkOptions(argumentValues []string) *Options {
+	options := NewOptions(argumentValues)
+	options.AddOption(Option{ArgumentType: OptionArgumentTypeNone, Name: "v", Type: OptionTypeShort})
+	options.AddOption(Option{ArgumentType: OptionArgumentTypeNone, Name: "q", Type: OptionTypeShort})
+	options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "o", Type: OptionTypeShort})
+	options.AddOption(Option{ArgumentType: OptionArgumentTypeNone, Name: "verbose", Type: OptionTypeLong})
+	options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "output", Type: OptionTypeLong})
+	return options
+}
+
+func BenchmarkParse1000Short(b *testing.B) {
+	argumentValues := make([]string, 1000)
+	for i := range argumentValues {
+		argumentValues[i] = "-v"
+	}
+	for i := 0; i < b.N; i++ {
+		newBenchmarkOptions(argumentValues).Parse()
+	}
+}
+
+// maxHappyPathAllocs bounds the allocations the benchmarks below are allowed to make per Parse call. It isn't zero: ParsedOption.Value is an interface{}, so boxing a ParsedOptionValue into it allocates regardless of how the scan itself is written, and the public API (ParsedOption included) is required to stay unchanged. This is deliberately a loose approximate regression guard against the O(n^2) string/[]rune round-tripping parseLongOption and parseShortOptions used to do, not a tight measured bound on the current allocs/op (9/5 at the time of writing) — it shouldn't flake on an unrelated Go-version or inlining change.
+const maxHappyPathAllocs = 32
+
+func BenchmarkParseBundled(b *testing.B) {
+	// "-vqovalue" bundles two argument-less flags with a required-argument option that claims the cluster remainder: the happy path the request asked this benchmark to exercise.
+	argumentValues := []string{"-vqovalue"}
+	options := newBenchmarkOptions(argumentValues)
+	allocations := testing.AllocsPerRun(b.N, func() {
+		options.Parsed = nil
+		options.nextArgumentValueIndex = 0
+		options.skipArgumentsOnNextParseIteration = 0
+		options.Parse()
+	})
+	b.ReportMetric(allocations, "happy-path-allocs/op")
+	if allocations > maxHappyPathAllocs {
+		b.Fatalf("BenchmarkParseBundled: got %.2f allocs/op, want <= %d", allocations, maxHappyPathAllocs)
+	}
+}
+
+func BenchmarkParseLongWithEquals(b *testing.B) {
+	argumentValues := []string{"--output=value", "--verbose"}
+	options := newBenchmarkOptions(argumentValues)
+	allocations := testing.AllocsPerRun(b.N, func() {
+		options.Parsed = nil
+		options.nextArgumentValueIndex = 0
+		options.skipArgumentsOnNextParseIteration = 0
+		options.Parse()
+	})
+	b.ReportMetric(allocations, "happy-path-allocs/op")
+	if allocations > maxHappyPathAllocs {
+		b.Fatalf("BenchmarkParseLongWithEquals: got %.2f allocs/op, want <= %d", allocations, maxHappyPathAllocs)
+	}
+}
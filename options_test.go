@@ -45,7 +45,7 @@ func TestOptions(t *testing.T) {
 				if !parsedOption.Error {
 					t.Fail()
 				}
-				err := newUnknownOptionParseError(options, OptionTypeShort, []rune("x"))
+				err := newUnknownOptionParseError(options, OptionTypeShort, "x")
 				if !reflect.DeepEqual(parsedOption.Value, err) {
 					t.Fail()
 				}
@@ -53,7 +53,7 @@ func TestOptions(t *testing.T) {
 				if !parsedOption.Error {
 					t.Fail()
 				}
-				err := newUnknownOptionParseError(options, OptionTypeLong, []rune("foo"))
+				err := newUnknownOptionParseError(options, OptionTypeLong, "foo")
 				if !reflect.DeepEqual(parsedOption.Value, err) {
 					t.Fail()
 				}
@@ -73,7 +73,7 @@ func TestOptions(t *testing.T) {
 						if !parsedOption.Error {
 							t.Fail()
 						}
-						err := newUnknownOptionParseError(options, OptionTypeShort, []rune("x"))
+						err := newUnknownOptionParseError(options, OptionTypeShort, "x")
 						if !reflect.DeepEqual(parsedOption.Value, err) {
 							t.Fail()
 						}
@@ -85,4 +85,152 @@ func TestOptions(t *testing.T) {
 			})
 		}
 	})
+	t.Run("\"--\" separator is consumed but everything after it is kept verbatim.", func(t *testing.T) {
+		t.Parallel()
+		argumentValues := []string{"-v", "--", "-v", "--foo", "bar"}
+		options := NewOptions(argumentValues)
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeNone, Name: "v", Type: OptionTypeShort})
+		optionsResult := options.Parse()
+		if len(optionsResult.Options) != 1 {
+			t.Fail()
+		}
+		expectedRemaining := []string{"-v", "--foo", "bar"}
+		if !reflect.DeepEqual(optionsResult.RemainingArgumentValues, expectedRemaining) {
+			t.Fail()
+		}
+	})
+	t.Run("A bare argument value stops option parsing, leaving it and everything after it as remaining.", func(t *testing.T) {
+		t.Parallel()
+		argumentValues := []string{"snapshot", "-v"}
+		options := NewOptions(argumentValues)
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeNone, Name: "v", Type: OptionTypeShort})
+		optionsResult := options.Parse()
+		if len(optionsResult.Options) != 0 {
+			t.Fail()
+		}
+		expectedRemaining := []string{"snapshot", "-v"}
+		if !reflect.DeepEqual(optionsResult.RemainingArgumentValues, expectedRemaining) {
+			t.Fail()
+		}
+	})
+	t.Run("Optional-argument options never consume the next argument value.", func(t *testing.T) {
+		t.Parallel()
+		t.Run("Long option without \"=\" leaves the argument empty.", func(t *testing.T) {
+			t.Parallel()
+			argumentValues := []string{"--opt", "VALUE"}
+			options := NewOptions(argumentValues)
+			options.AddOption(Option{ArgumentType: OptionArgumentTypeOptional, Name: "opt", Type: OptionTypeLong})
+			optionsResult := options.Parse()
+			parsedValue := optionsResult.Options[0].Value.(ParsedOptionValue)
+			if parsedValue.Argument != "" {
+				t.Fail()
+			}
+			if len(optionsResult.RemainingArgumentValues) != 1 || optionsResult.RemainingArgumentValues[0] != "VALUE" {
+				t.Fail()
+			}
+		})
+		t.Run("Long option with \"=\" attaches the value.", func(t *testing.T) {
+			t.Parallel()
+			argumentValues := []string{"--opt=VALUE"}
+			options := NewOptions(argumentValues)
+			options.AddOption(Option{ArgumentType: OptionArgumentTypeOptional, Name: "opt", Type: OptionTypeLong})
+			optionsResult := options.Parse()
+			parsedValue := optionsResult.Options[0].Value.(ParsedOptionValue)
+			if parsedValue.Argument != "VALUE" {
+				t.Fail()
+			}
+		})
+		t.Run("Short option \"-o\" without a cluster remainder leaves the argument empty.", func(t *testing.T) {
+			t.Parallel()
+			argumentValues := []string{"-o", "VALUE"}
+			options := NewOptions(argumentValues)
+			options.AddOption(Option{ArgumentType: OptionArgumentTypeOptional, Name: "o", Type: OptionTypeShort})
+			optionsResult := options.Parse()
+			parsedValue := optionsResult.Options[0].Value.(ParsedOptionValue)
+			if parsedValue.Argument != "" {
+				t.Fail()
+			}
+			if len(optionsResult.RemainingArgumentValues) != 1 || optionsResult.RemainingArgumentValues[0] != "VALUE" {
+				t.Fail()
+			}
+		})
+		t.Run("Short option \"-oVALUE\" attaches the cluster remainder.", func(t *testing.T) {
+			t.Parallel()
+			argumentValues := []string{"-oVALUE"}
+			options := NewOptions(argumentValues)
+			options.AddOption(Option{ArgumentType: OptionArgumentTypeOptional, Name: "o", Type: OptionTypeShort})
+			optionsResult := options.Parse()
+			parsedValue := optionsResult.Options[0].Value.(ParsedOptionValue)
+			if parsedValue.Argument != "VALUE" {
+				t.Fail()
+			}
+		})
+		t.Run("Bundled \"-abcVALUE\" only gives the remainder to the last, optional-argument option \"c\".", func(t *testing.T) {
+			t.Parallel()
+			argumentValues := []string{"-abcVALUE"}
+			options := NewOptions(argumentValues)
+			options.AddOption(Option{ArgumentType: OptionArgumentTypeNone, Name: "a", Type: OptionTypeShort})
+			options.AddOption(Option{ArgumentType: OptionArgumentTypeNone, Name: "b", Type: OptionTypeShort})
+			options.AddOption(Option{ArgumentType: OptionArgumentTypeOptional, Name: "c", Type: OptionTypeShort})
+			optionsResult := options.Parse()
+			if len(optionsResult.Options) != 3 {
+				t.Fail()
+			}
+			for i, parsedOption := range optionsResult.Options {
+				parsedValue := parsedOption.Value.(ParsedOptionValue)
+				switch i {
+				case 0:
+					if parsedValue.Name != "a" || parsedValue.Argument != "" {
+						t.Fail()
+					}
+				case 1:
+					if parsedValue.Name != "b" || parsedValue.Argument != "" {
+						t.Fail()
+					}
+				case 2:
+					if parsedValue.Name != "c" || parsedValue.Argument != "VALUE" {
+						t.Fail()
+					}
+				}
+			}
+		})
+	})
+	t.Run("AddCommand routes the remaining argument values to the matched subcommand.", func(t *testing.T) {
+		t.Parallel()
+		argumentValues := []string{"--repo=origin", "snapshot", "-v"}
+		options := NewOptions(argumentValues)
+		options.AddOption(Option{ArgumentType: OptionArgumentTypeRequired, Name: "repo", Type: OptionTypeLong})
+		sub := NewOptions(nil)
+		sub.AddOption(Option{ArgumentType: OptionArgumentTypeNone, Name: "v", Type: OptionTypeShort})
+		options.AddCommand("snapshot", sub)
+		optionsResult := options.Parse()
+		if optionsResult.Command == nil || optionsResult.Command.Name != "snapshot" {
+			t.Fail()
+		}
+		if !reflect.DeepEqual(optionsResult.CommandPath, []string{"snapshot"}) {
+			t.Fail()
+		}
+		subResult := sub.Parsed
+		if subResult == nil || len(subResult.Options) != 1 {
+			t.Fail()
+		}
+		if len(optionsResult.RemainingArgumentValues) != 0 {
+			t.Fail()
+		}
+	})
+	t.Run("AddCommand leaves remaining argument values alone when the first one doesn't match a registered command.", func(t *testing.T) {
+		t.Parallel()
+		argumentValues := []string{"unknown", "-v"}
+		options := NewOptions(argumentValues)
+		sub := NewOptions(nil)
+		options.AddCommand("snapshot", sub)
+		optionsResult := options.Parse()
+		if optionsResult.Command != nil {
+			t.Fail()
+		}
+		expectedRemaining := []string{"unknown", "-v"}
+		if !reflect.DeepEqual(optionsResult.RemainingArgumentValues, expectedRemaining) {
+			t.Fail()
+		}
+	})
 }
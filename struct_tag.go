@@ -0,0 +1,249 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Setter is implemented by custom option value types which need control over how their own string argument gets parsed (e.g., a field of type "IPAddress" or "Duration-with-units").
+type Setter interface {
+	Set(string) error
+}
+
+// InvalidSpecError represents an error for when a spec passed to ParseInto isn't a pointer to a struct, or declares a field whose type isn't supported by the reflection/binding subsystem.
+type InvalidSpecError struct {
+	message   string
+	name      string
+	FieldName string
+}
+
+func newInvalidSpecError(fieldName string, reason string) InvalidSpecError {
+	return InvalidSpecError{
+		message:   fmt.Sprintf("Invalid \"ParseInto\" spec field \"%s\": %s.", fieldName, reason),
+		name:      "InvalidSpecError",
+		FieldName: fieldName,
+	}
+}
+
+func (error_ InvalidSpecError) Error() string {
+	return error_.message
+}
+
+// MissingRequiredOptionError represents an error for when a required option (a struct-tag-declared field marked "required", or an Option with Required set whose values are merged from Options.BindEnv/Options.LoadINI) wasn't provided on the command line or by any of its fallbacks.
+type MissingRequiredOptionError struct {
+	message    string
+	name       string
+	OptionName string
+}
+
+func newMissingRequiredOptionError(optionName string) MissingRequiredOptionError {
+	return MissingRequiredOptionError{
+		message:    fmt.Sprintf("Option \"%s\" is required.", optionName),
+		name:       "MissingRequiredOptionError",
+		OptionName: optionName,
+	}
+}
+
+func (error_ MissingRequiredOptionError) Error() string {
+	return error_.message
+}
+
+var setterType = reflect.TypeOf((*Setter)(nil)).Elem()
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// structFieldBinding ties a struct field to the Option descriptor(s) registered on its behalf.
+type structFieldBinding struct {
+	defaultValue string
+	envVar       string
+	field        reflect.Value
+	longName     string
+	required     bool
+	shortName    string
+}
+
+// ParseInto registers an Option for each "long" and each "short" name of every exported, tagged field of spec (a pointer to a struct) with a new Options instance, parses argv against them, and populates spec's fields directly from the result — the "short"/"long"/"description"/"required"/"default"/"env" struct tags drive registration, in the style of jessevdk/go-flags.
+func ParseInto(spec interface{}, argv []string) (*ParseResult, error) {
+	specValue := reflect.ValueOf(spec)
+	if specValue.Kind() != reflect.Ptr || specValue.Elem().Kind() != reflect.Struct {
+		return nil, newInvalidSpecError("", "spec must be a pointer to a struct")
+	}
+	structValue := specValue.Elem()
+	structType := structValue.Type()
+	options := NewOptions(argv)
+	bindings := make([]structFieldBinding, 0, structType.NumField())
+	for fieldIndex := 0; fieldIndex < structType.NumField(); fieldIndex++ {
+		fieldDescriptor := structType.Field(fieldIndex)
+		if fieldDescriptor.PkgPath != "" {
+			continue
+		}
+		longName := fieldDescriptor.Tag.Get("long")
+		shortName := fieldDescriptor.Tag.Get("short")
+		if longName == "" && shortName == "" {
+			continue
+		}
+		field := structValue.Field(fieldIndex)
+		if !isSupportedStructFieldType(field) {
+			return nil, newInvalidSpecError(fieldDescriptor.Name, "unsupported field type")
+		}
+		argumentType := OptionArgumentTypeRequired
+		if field.Kind() == reflect.Bool {
+			argumentType = OptionArgumentTypeNone
+		}
+		required := fieldDescriptor.Tag.Get("required") == "true"
+		helpDescription := fieldDescriptor.Tag.Get("description")
+		if longName != "" {
+			options.AddOption(Option{
+				ArgumentType:    argumentType,
+				HelpDescription: helpDescription,
+				Name:            longName,
+				Required:        required,
+				Type:            OptionTypeLong,
+			})
+		}
+		if shortName != "" {
+			options.AddOption(Option{
+				ArgumentType:    argumentType,
+				HelpDescription: helpDescription,
+				Name:            shortName,
+				Required:        required,
+				Type:            OptionTypeShort,
+			})
+		}
+		bindings = append(bindings, structFieldBinding{
+			defaultValue: fieldDescriptor.Tag.Get("default"),
+			envVar:       fieldDescriptor.Tag.Get("env"),
+			field:        field,
+			longName:     longName,
+			required:     required,
+			shortName:    shortName,
+		})
+	}
+	result := options.Parse()
+	for _, binding := range bindings {
+		if err := applyStructFieldBinding(result, binding); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// isSupportedStructFieldType reports whether field is a type setStructFieldValue knows how to populate: a Setter, a time.Duration, a bool, a string, a signed integer, or a []string.
+func isSupportedStructFieldType(field reflect.Value) bool {
+	if field.CanAddr() && field.Addr().Type().Implements(setterType) {
+		return true
+	}
+	switch {
+	case field.Type() == durationType:
+		return true
+	case field.Kind() == reflect.Bool:
+		return true
+	case field.Kind() == reflect.String:
+		return true
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int8 || field.Kind() == reflect.Int16 || field.Kind() == reflect.Int32 || field.Kind() == reflect.Int64:
+		return true
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+func applyStructFieldBinding(result *ParseResult, binding structFieldBinding) error {
+	matched := false
+	for _, parsedOption := range result.Options {
+		if parsedOption.Error {
+			continue
+		}
+		parsedValue := parsedOption.Value.(ParsedOptionValue)
+		if (binding.longName != "" && parsedValue.Type == OptionTypeLong && parsedValue.Name == binding.longName) ||
+			(binding.shortName != "" && parsedValue.Type == OptionTypeShort && parsedValue.Name == binding.shortName) {
+			matched = true
+			if parsedValue.ArgumentType == OptionArgumentTypeNone {
+				if err := setStructFieldValue(binding.field, "true"); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := setStructFieldValue(binding.field, parsedValue.Argument); err != nil {
+				return err
+			}
+		}
+	}
+	if matched {
+		return nil
+	}
+	if binding.envVar != "" {
+		if envValue, set := os.LookupEnv(binding.envVar); set {
+			return setStructFieldValue(binding.field, envValue)
+		}
+	}
+	if binding.defaultValue != "" {
+		return setStructFieldValue(binding.field, binding.defaultValue)
+	}
+	if binding.required {
+		name := binding.longName
+		if name == "" {
+			name = binding.shortName
+		}
+		return newMissingRequiredOptionError(name)
+	}
+	return nil
+}
+
+func setStructFieldValue(field reflect.Value, rawValue string) error {
+	if field.CanAddr() && field.Addr().Type().Implements(setterType) {
+		return field.Addr().Interface().(Setter).Set(rawValue)
+	}
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(rawValue)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	case field.Kind() == reflect.Bool:
+		parsedBool, err := strconv.ParseBool(rawValue)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsedBool)
+		return nil
+	case field.Kind() == reflect.String:
+		field.SetString(rawValue)
+		return nil
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int8 || field.Kind() == reflect.Int16 || field.Kind() == reflect.Int32 || field.Kind() == reflect.Int64:
+		parsedInt, err := strconv.ParseInt(rawValue, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsedInt)
+		return nil
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		field.Set(reflect.Append(field, reflect.ValueOf(rawValue)))
+		return nil
+	default:
+		return newInvalidSpecError(field.Type().Name(), "unsupported field type")
+	}
+}
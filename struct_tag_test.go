@@ -0,0 +1,145 @@
+/**
+ ****************************************************************************
+ * Copyright 2017 Jonathan Barronville <jonathan@belairlabs.com>            *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *     http://www.apache.org/licenses/LICENSE-2.0                           *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ****************************************************************************
+ */
+
+package thoruh
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInto(t *testing.T) {
+	t.Run("Populates fields from short, long, and repeated flags.", func(t *testing.T) {
+		t.Parallel()
+		var spec struct {
+			Repo    string        `long:"repo" short:"r"`
+			Verbose bool          `long:"verbose"`
+			Timeout time.Duration `long:"timeout"`
+			Files   []string      `long:"file"`
+		}
+		argumentValues := []string{"-r", "origin", "--verbose", "--timeout=2s", "--file=a", "--file=b"}
+		if _, err := ParseInto(&spec, argumentValues); err != nil {
+			t.Fatal(err)
+		}
+		if spec.Repo != "origin" {
+			t.Fail()
+		}
+		if !spec.Verbose {
+			t.Fail()
+		}
+		if spec.Timeout != 2*time.Second {
+			t.Fail()
+		}
+		if !reflect.DeepEqual(spec.Files, []string{"a", "b"}) {
+			t.Fail()
+		}
+	})
+	t.Run("A \"default\" tag fills in a field absent from argv without an error.", func(t *testing.T) {
+		t.Parallel()
+		var spec struct {
+			Repo string `long:"repo" required:"true" default:"origin"`
+		}
+		result, err := ParseInto(&spec, []string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if spec.Repo != "origin" {
+			t.Fail()
+		}
+		for _, parsedOption := range result.Options {
+			if parsedOption.Error {
+				t.Fail()
+			}
+		}
+	})
+	t.Run("A missing required field with no default or env fallback reports MissingRequiredOptionError.", func(t *testing.T) {
+		t.Parallel()
+		var spec struct {
+			Repo string `long:"repo" required:"true"`
+		}
+		_, err := ParseInto(&spec, []string{})
+		if _, ok := err.(MissingRequiredOptionError); !ok {
+			t.Fail()
+		}
+	})
+	t.Run("An \"env\" tag fills in a field absent from argv.", func(t *testing.T) {
+		t.Setenv("THORUH_TEST_REPO", "upstream")
+		var spec struct {
+			Repo string `long:"repo" env:"THORUH_TEST_REPO"`
+		}
+		if _, err := ParseInto(&spec, []string{}); err != nil {
+			t.Fatal(err)
+		}
+		if spec.Repo != "upstream" {
+			t.Fail()
+		}
+	})
+	t.Run("An int field is parsed against its own bit width instead of silently truncating out-of-range input.", func(t *testing.T) {
+		t.Parallel()
+		var spec struct {
+			Count int8 `long:"count"`
+		}
+		if _, err := ParseInto(&spec, []string{"--count=500"}); err == nil {
+			t.Fail()
+		}
+	})
+	t.Run("An in-range int field is still populated correctly.", func(t *testing.T) {
+		t.Parallel()
+		var spec struct {
+			Count int8 `long:"count"`
+		}
+		if _, err := ParseInto(&spec, []string{"--count=100"}); err != nil {
+			t.Fatal(err)
+		}
+		if spec.Count != 100 {
+			t.Fail()
+		}
+	})
+	t.Run("A field implementing Setter is populated via its own Set method.", func(t *testing.T) {
+		t.Parallel()
+		var spec struct {
+			Level logLevel `long:"level"`
+		}
+		if _, err := ParseInto(&spec, []string{"--level=debug"}); err != nil {
+			t.Fatal(err)
+		}
+		if spec.Level != logLevel("DEBUG") {
+			t.Fail()
+		}
+	})
+	t.Run("An unsupported field type is rejected at registration time, not left at its zero value.", func(t *testing.T) {
+		t.Parallel()
+		var spec struct {
+			Ratio float64 `long:"ratio"`
+		}
+		_, err := ParseInto(&spec, []string{})
+		if _, ok := err.(InvalidSpecError); !ok {
+			t.Fail()
+		}
+	})
+}
+
+// logLevel is a Setter-implementing test type exercising ParseInto's custom-type binding path.
+type logLevel string
+
+func (level *logLevel) Set(rawValue string) error {
+	*level = logLevel(strings.ToUpper(rawValue))
+	return nil
+}